@@ -0,0 +1,315 @@
+// Command easytags-lsp is a minimal Language Server Protocol server that
+// offers easytags as editor code actions ("Add struct tags", "Remove
+// struct tags", "Add omitempty") instead of requiring users to shell out
+// to the easytags CLI and overwrite files by hand.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/win5do/easytags/pkg/easytags"
+)
+
+func main() {
+	s := &server{
+		docs: make(map[string][]byte),
+		in:   bufio.NewReader(os.Stdin),
+		out:  os.Stdout,
+	}
+	if err := s.run(); err != nil && err != io.EOF {
+		log.Fatal(err)
+	}
+}
+
+type server struct {
+	docs map[string][]byte // uri -> current document text
+	in   *bufio.Reader
+	out  io.Writer
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (s *server) run() error {
+	for {
+		req, err := readMessage(s.in)
+		if err != nil {
+			return err
+		}
+		s.handle(req)
+	}
+}
+
+// readMessage reads one Content-Length-framed JSON-RPC message.
+func readMessage(r *bufio.Reader) (*request, error) {
+	var length int
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "Content-Length:") {
+			n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "Content-Length:")))
+			if err != nil {
+				return nil, fmt.Errorf("easytags-lsp: invalid Content-Length: %w", err)
+			}
+			length = n
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("easytags-lsp: decoding request: %w", err)
+	}
+	return &req, nil
+}
+
+func (s *server) writeResponse(id json.RawMessage, result interface{}) {
+	s.send(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *server) send(msg response) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("easytags-lsp: encoding response: %v", err)
+		return
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *server) handle(req *request) {
+	switch req.Method {
+	case "initialize":
+		s.writeResponse(req.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, // full document sync
+				"codeActionProvider": true,
+			},
+		})
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err := json.Unmarshal(req.Params, &p); err == nil {
+			s.docs[p.TextDocument.URI] = []byte(p.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err := json.Unmarshal(req.Params, &p); err == nil && len(p.ContentChanges) > 0 {
+			s.docs[p.TextDocument.URI] = []byte(p.ContentChanges[len(p.ContentChanges)-1].Text)
+		}
+	case "textDocument/codeAction":
+		var p codeActionParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			s.writeResponse(req.ID, nil)
+			return
+		}
+		s.writeResponse(req.ID, s.codeActions(p))
+	case "shutdown":
+		s.writeResponse(req.ID, nil)
+	case "exit":
+		os.Exit(0)
+	default:
+		if req.ID != nil {
+			s.writeResponse(req.ID, nil)
+		}
+	}
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type didOpenParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type codeActionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Range        lspRange               `json:"range"`
+}
+
+type codeAction struct {
+	Title string         `json:"title"`
+	Kind  string         `json:"kind"`
+	Edit  *workspaceEdit `json:"edit,omitempty"`
+}
+
+type workspaceEdit struct {
+	Changes map[string][]textEdit `json:"changes"`
+}
+
+type textEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+// codeActions builds the "Add struct tags" / "Remove struct tags" /
+// "Add omitempty" actions for the struct under p.Range, if any.
+func (s *server) codeActions(p codeActionParams) []codeAction {
+	src, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+
+	offset := offsetAt(src, p.Range.Start)
+
+	variants := []struct {
+		title string
+		opts  easytags.Options
+	}{
+		{"Add struct tags", easytags.Options{Tags: []*easytags.TagOpt{{Tag: "json", Case: easytags.CaseCamel}}, SkipUnexported: true}},
+		{"Add omitempty", easytags.Options{
+			Tags:           []*easytags.TagOpt{{Tag: "json", Case: easytags.CaseCamel}},
+			Omitempty:      true,
+			AddOptions:     []*easytags.OptionMutation{{Tag: "json", Options: []string{"omitempty"}}},
+			SkipUnexported: true,
+		}},
+		{"Remove struct tags", easytags.Options{Remove: true, SkipUnexported: true}},
+	}
+
+	var actions []codeAction
+	for _, v := range variants {
+		out, err := rewriteStructAt(src, offset, v.opts)
+		if err != nil || out == nil {
+			continue
+		}
+		actions = append(actions, codeAction{
+			Title: v.title,
+			Kind:  "refactor.rewrite",
+			Edit: &workspaceEdit{
+				Changes: map[string][]textEdit{
+					p.TextDocument.URI: {{
+						Range:   fullRange(src),
+						NewText: string(out),
+					}},
+				},
+			},
+		})
+	}
+	return actions
+}
+
+// rewriteStructAt parses src, finds the innermost struct type containing
+// offset, rewrites only that struct's tags per opts, and returns the
+// reformatted file. It returns a nil slice (no error) if no struct
+// contains offset.
+func rewriteStructAt(src []byte, offset int, opts easytags.Options) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("easytags-lsp: parsing document: %w", err)
+	}
+
+	file := fset.File(f.Pos())
+	if offset < 0 || offset > file.Size() {
+		return nil, nil
+	}
+	pos := file.Pos(offset)
+
+	var target *ast.StructType
+	ast.Inspect(f, func(n ast.Node) bool {
+		st, ok := n.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		if st.Pos() <= pos && pos <= st.End() {
+			target = st
+		}
+		return true
+	})
+	if target == nil {
+		return nil, nil
+	}
+
+	if err := easytags.RewriteStruct(target, opts); err != nil {
+		return nil, err
+	}
+
+	var buf strings.Builder
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, fmt.Errorf("easytags-lsp: formatting document: %w", err)
+	}
+	return []byte(buf.String()), nil
+}
+
+func fullRange(src []byte) lspRange {
+	lines := strings.Count(string(src), "\n")
+	last := strings.LastIndexByte(string(src), '\n')
+	lastLineLen := len(src) - last - 1
+	return lspRange{
+		Start: position{Line: 0, Character: 0},
+		End:   position{Line: lines, Character: lastLineLen},
+	}
+}
+
+// offsetAt converts a 0-based LSP line/character position into a byte
+// offset into src. Character is treated as a byte offset within the
+// line, which is sufficient for ASCII Go source.
+func offsetAt(src []byte, pos position) int {
+	offset := 0
+	line := 0
+	for offset < len(src) && line < pos.Line {
+		idx := strings.IndexByte(string(src[offset:]), '\n')
+		if idx < 0 {
+			return len(src)
+		}
+		offset += idx + 1
+		line++
+	}
+	return offset + pos.Character
+}
@@ -1,44 +1,23 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
-	"go/ast"
-	"go/format"
-	"go/parser"
-	"go/token"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
-	"unicode"
 
 	"github.com/spf13/cobra"
+	"github.com/win5do/easytags/pkg/easytags"
 )
 
 const (
-	case_camel  = "camel"
-	case_snake  = "snake"
-	case_pascal = "pascal"
-
-	defaultTag  = "json"
-	defaultCase = case_camel
-
 	example = `
 	easytags -o <file_name> json:camel
 	easytags -r -o <file_name> json:pascal bson:snake
+	easytags --config easytags.toml <file_name>
 `
 )
 
-var (
-	gFlagOmitempty bool
-)
-
-type TagOpt struct {
-	Tag  string
-	Case string
-}
-
 func main() {
 	rootCmd := &cobra.Command{
 		Use:     "easytags [options] <file_name> [<tag:case>...]",
@@ -48,185 +27,119 @@ func main() {
 
 	remove := rootCmd.Flags().BoolP("remove", "r", false, "removes all tags if none was provided")
 	omitempty := rootCmd.Flags().BoolP("omitempty", "o", false, "add omitempty")
+	configPath := rootCmd.Flags().String("config", "", "path to an easytags.toml/easytags.yaml config file")
+	addOptionsFlag := rootCmd.Flags().StringArray("add-options", nil, "append options to an existing tag, e.g. json=omitempty,string")
+	removeOptionsFlag := rootCmd.Flags().StringArray("remove-options", nil, "remove options from an existing tag, e.g. json=omitempty")
+	clearOptionsFlag := rootCmd.Flags().StringSlice("clear-options", nil, "drop all options from an existing tag, keeping its name, e.g. json")
+	skipUnexported := rootCmd.Flags().Bool("skip-unexported", true, "skip fields that are not exported")
+	fieldFlag := rootCmd.Flags().String("field", "", "only touch fields whose name matches this regex")
+	check := rootCmd.Flags().Bool("check", false, "print a diff and exit 1 if any file would change, without writing")
+	dryRun := rootCmd.Flags().Bool("dry-run", false, "alias for --check")
 
 	rootCmd.Run = func(cmd *cobra.Command, args []string) {
-		gFlagOmitempty = *omitempty
-
-		var tags []*TagOpt
-
-		if len(args) < 2 {
+		if len(args) < 1 {
 			if err := rootCmd.Help(); err != nil {
 				panic(err)
 			}
 			return
 		}
 
-		for _, e := range args[1:] {
-			t := strings.SplitN(strings.TrimSpace(e), ":", 2)
-			tag := &TagOpt{t[0], defaultCase}
-			if len(t) == 2 {
-				tag.Case = t[1]
-			}
-			tags = append(tags, tag)
-		}
+		opts := easytags.Options{Remove: *remove, Omitempty: *omitempty, SkipUnexported: *skipUnexported}
 
-		if len(tags) == 0 && *remove == false {
-			tags = append(tags, &TagOpt{defaultTag, defaultCase})
+		path := *configPath
+		if path == "" {
+			path = easytags.FindConfig(filepath.Dir(args[0]))
 		}
-		for _, arg := range args {
-			files, err := filepath.Glob(arg)
+		if path != "" {
+			cfg, err := easytags.LoadConfig(path)
 			if err != nil {
 				fmt.Println(err)
 				os.Exit(1)
 				return
 			}
-			for _, f := range files {
-				GenerateTags(f, tags, *remove)
-			}
-		}
-	}
-
-	if err := rootCmd.Execute(); err != nil {
-		panic(err)
-	}
-}
-
-// GenerateTags generates snake case json tags so that you won't need to write them. Can be also extended to xml or sql tags
-func GenerateTags(fileName string, tags []*TagOpt, remove bool) {
-	fset := token.NewFileSet() // positions are relative to fset
-	// Parse the file given in arguments
-	f, err := parser.ParseFile(fset, fileName, nil, parser.ParseComments)
-	if err != nil {
-		fmt.Printf("Error parsing file %v", err)
-		return
-	}
-
-	// range over the objects in the scope of this generated AST and check for StructType. Then range over fields
-	// contained in that struct.
-
-	ast.Inspect(f, func(n ast.Node) bool {
-		switch t := n.(type) {
-		case *ast.StructType:
-			processTags(t, tags, remove)
-			return false
+			opts.Omitempty = opts.Omitempty || cfg.Omitempty
+			opts.Tags = cfg.TagOpts()
+			opts.FieldRule = &cfg.Fields
 		}
-		return true
-	})
-
-	// overwrite the file with modified version of ast.
-	write, err := os.Create(fileName)
-	if err != nil {
-		fmt.Printf("Error opening file %v", err)
-		return
-	}
-	defer write.Close()
-	w := bufio.NewWriter(write)
-	err = format.Node(w, fset, f)
-	if err != nil {
-		fmt.Printf("Error formating file %s", err)
-		return
-	}
-	w.Flush()
-}
 
-func parseTags(field *ast.Field, tags []*TagOpt) string {
-	var tagValues []string
-	fieldName := field.Names[0].String()
-	for _, tag := range tags {
-		var value string
-		existingTagReg := regexp.MustCompile(fmt.Sprintf("%s:\"[^\"]+\"", tag.Tag))
-		existingTag := existingTagReg.FindString(field.Tag.Value)
-		if existingTag == "" {
-			var name string
-			switch tag.Case {
-			case case_snake:
-				name = ToSnake(fieldName)
-			case case_camel:
-				name = ToCamel(fieldName)
-			case case_pascal:
-				name = fieldName
-			default:
-				fmt.Printf("Unknown case option %s", tag.Case)
-			}
-			var tplStr string
-			if gFlagOmitempty {
-				tplStr = "%s:\"%s,omitempty\""
-			} else {
-				tplStr = "%s:\"%s\""
+		if *fieldFlag != "" {
+			if opts.FieldRule == nil {
+				opts.FieldRule = &easytags.FieldRule{}
 			}
-			value = fmt.Sprintf(tplStr, tag.Tag, name)
-
-			tagValues = append(tagValues, value)
+			opts.FieldRule.Include = *fieldFlag
 		}
 
-	}
-	updatedTags := strings.Fields(strings.Trim(field.Tag.Value, "`"))
-
-	if len(tagValues) > 0 {
-		updatedTags = append(updatedTags, tagValues...)
-	}
-	newValue := "`" + strings.Join(updatedTags, " ") + "`"
-
-	return newValue
-}
-
-func processTags(x *ast.StructType, tags []*TagOpt, remove bool) {
-	for _, field := range x.Fields.List {
-		if len(field.Names) == 0 {
-			continue
+		var err error
+		if opts.AddOptions, err = easytags.ParseOptionMutations(*addOptionsFlag); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+			return
 		}
-		if !unicode.IsUpper(rune(field.Names[0].String()[0])) {
-			// not exported
-			continue
+		if opts.RemoveOptions, err = easytags.ParseOptionMutations(*removeOptionsFlag); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+			return
 		}
+		opts.ClearOptions = *clearOptionsFlag
 
-		if remove {
-			field.Tag = nil
+		hasOptionMutation := len(opts.AddOptions) > 0 || len(opts.RemoveOptions) > 0 || len(opts.ClearOptions) > 0
+
+		if len(opts.Tags) == 0 && !hasOptionMutation {
+			if len(args) < 2 {
+				if err := rootCmd.Help(); err != nil {
+					panic(err)
+				}
+				return
+			}
+			for _, e := range args[1:] {
+				t := strings.SplitN(strings.TrimSpace(e), ":", 2)
+				tag := &easytags.TagOpt{Tag: t[0], Case: easytags.CaseCamel}
+				if len(t) == 2 {
+					tag.Case = t[1]
+				}
+				opts.Tags = append(opts.Tags, tag)
+			}
 		}
 
-		if field.Tag == nil {
-			field.Tag = &ast.BasicLit{}
-			field.Tag.ValuePos = field.Type.Pos() + 1
-			field.Tag.Kind = token.STRING
+		if len(opts.Tags) == 0 && !opts.Remove && !hasOptionMutation {
+			opts.Tags = append(opts.Tags, &easytags.TagOpt{Tag: "json", Case: easytags.CaseCamel})
 		}
 
-		newTags := parseTags(field, tags)
-		field.Tag.Value = newTags
-	}
-}
+		checking := *check || *dryRun
+		anyChanged := false
 
-// ToSnake convert the given string to snake case following the Golang format:
-// acronyms are converted to lower-case and preceded by an underscore.
-// Original source : https://gist.github.com/elwinar/14e1e897fdbe4d3432e1
-func ToSnake(in string) string {
-	runes := []rune(in)
-	length := len(runes)
-
-	var out []rune
-	for i := 0; i < length; i++ {
-		if i > 0 && unicode.IsUpper(runes[i]) && ((i+1 < length && unicode.IsLower(runes[i+1])) || unicode.IsLower(runes[i-1])) {
-			out = append(out, '_')
+		for _, arg := range args {
+			files, err := filepath.Glob(arg)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+				return
+			}
+			for _, f := range files {
+				if checking {
+					diff, changed, err := easytags.Diff(f, opts)
+					if err != nil {
+						fmt.Println(err)
+						continue
+					}
+					if changed {
+						anyChanged = true
+						fmt.Print(diff)
+					}
+					continue
+				}
+				if err := easytags.RewriteFile(f, opts); err != nil {
+					fmt.Println(err)
+				}
+			}
 		}
-		out = append(out, unicode.ToLower(runes[i]))
-	}
-	return string(out)
-}
-
-// ToCamel convert the given string to camelCase
-func ToCamel(in string) string {
-	runes := []rune(in)
-	length := len(runes)
 
-	var i int
-	for i = 0; i < length; i++ {
-		if unicode.IsLower(runes[i]) {
-			break
+		if checking && anyChanged {
+			os.Exit(1)
 		}
-		runes[i] = unicode.ToLower(runes[i])
 	}
-	if i != 1 && i != length {
-		i--
-		runes[i] = unicode.ToUpper(runes[i])
+
+	if err := rootCmd.Execute(); err != nil {
+		panic(err)
 	}
-	return string(runes)
 }
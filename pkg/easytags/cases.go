@@ -0,0 +1,136 @@
+package easytags
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Built-in case names usable in tag:case args, config files, and Options.
+const (
+	CaseCamel  = "camel"
+	CaseSnake  = "snake"
+	CasePascal = "pascal"
+	CaseKebab  = "kebab"
+)
+
+// caseRegistry maps a case name to the function that renders a field name
+// in that case. It is seeded with the built-in cases and can be extended
+// by callers of this package via RegisterCase.
+var caseRegistry = map[string]func(string) string{
+	CaseSnake:  ToSnake,
+	CaseCamel:  ToCamel,
+	CasePascal: ToPascal,
+	CaseKebab:  ToKebab,
+}
+
+// RegisterCase adds or overrides a named case transform, e.g. to support
+// screaming_snake, dot.case, or a locale-aware transform without changes
+// to this package.
+func RegisterCase(name string, fn func(string) string) {
+	caseRegistry[name] = fn
+}
+
+// splitWords breaks an identifier into its constituent words, preserving
+// each word's original casing. Besides explicit `-`/`_`/space separators,
+// a new word starts at a lower/digit→upper transition, and at the last
+// upper letter of a run of uppers that is followed by a lower letter
+// (so "HTTPServer" splits as "HTTP"+"Server", not letter by letter).
+func splitWords(in string) []string {
+	runes := []rune(in)
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	for i, r := range runes {
+		if r == '-' || r == '_' || r == ' ' {
+			flush()
+			continue
+		}
+		if i > 0 && isWordBoundary(runes, i) {
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+// isWordBoundary reports whether a new word starts at runes[i].
+func isWordBoundary(runes []rune, i int) bool {
+	cur := runes[i]
+	if !unicode.IsUpper(cur) {
+		return false
+	}
+	prev := runes[i-1]
+	if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+		return true
+	}
+	if unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+		return true
+	}
+	return false
+}
+
+// capitalize upper-cases the first rune of word, leaving the rest as-is
+// so that existing acronyms (e.g. "URL") aren't mangled.
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	runes := []rune(word)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// ToSnake converts the given string to snake_case. Acronyms are kept as
+// one word and hyphens and underscores separate words, so "HTTPSProxy"
+// becomes "https_proxy" and "my-field" becomes "my_field". Digits stay
+// attached to the word they follow: "OAuth2Token" becomes "o_auth2_token".
+func ToSnake(in string) string {
+	words := splitWords(in)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// ToCamel converts the given string to camelCase.
+func ToCamel(in string) string {
+	words := splitWords(in)
+	var out strings.Builder
+	for i, w := range words {
+		if i == 0 {
+			out.WriteString(strings.ToLower(w))
+			continue
+		}
+		out.WriteString(capitalize(w))
+	}
+	return out.String()
+}
+
+// ToPascal converts the given string to PascalCase.
+func ToPascal(in string) string {
+	words := splitWords(in)
+	var out strings.Builder
+	for _, w := range words {
+		out.WriteString(capitalize(w))
+	}
+	return out.String()
+}
+
+// ToKebab converts the given string to lower-case kebab-case, e.g. for
+// CLI flag tags: "RequestID" becomes "request-id".
+func ToKebab(in string) string {
+	words := splitWords(in)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "-")
+}
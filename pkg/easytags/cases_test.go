@@ -0,0 +1,93 @@
+package easytags
+
+import "testing"
+
+func TestToSnake(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"ID", "id"},
+		{"UserID", "user_id"},
+		{"URLPath", "url_path"},
+		{"HTTPSProxy", "https_proxy"},
+		{"my-field", "my_field"},
+		{"OAuth2Token", "o_auth2_token"},
+		{"Name", "name"},
+	}
+	for _, c := range cases {
+		if got := ToSnake(c.in); got != c.want {
+			t.Errorf("ToSnake(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestToCamel(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"ID", "id"},
+		{"UserID", "userID"},
+		{"URLPath", "urlPath"},
+		{"OAuth2Token", "oAuth2Token"},
+		{"my-field", "myField"},
+	}
+	for _, c := range cases {
+		if got := ToCamel(c.in); got != c.want {
+			t.Errorf("ToCamel(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestToPascal(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"my-field", "MyField"},
+		{"user_id", "UserId"},
+		{"HTTPSProxy", "HTTPSProxy"},
+		{"OAuth2Token", "OAuth2Token"},
+	}
+	for _, c := range cases {
+		got := ToPascal(c.in)
+		if got != c.want {
+			t.Errorf("ToPascal(%q) = %q, want %q", c.in, got, c.want)
+		}
+		if !isValidGoIdent(got) {
+			t.Errorf("ToPascal(%q) = %q is not a valid Go identifier", c.in, got)
+		}
+	}
+}
+
+func TestToKebab(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"UserID", "user-id"},
+		{"RequestID", "request-id"},
+		{"my_field", "my-field"},
+	}
+	for _, c := range cases {
+		if got := ToKebab(c.in); got != c.want {
+			t.Errorf("ToKebab(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func isValidGoIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z'):
+		case i > 0 && '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
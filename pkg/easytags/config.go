@@ -0,0 +1,102 @@
+package easytags
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configNames are the default file names looked up next to the target
+// file when no --config flag is given.
+var configNames = []string{"easytags.toml", "easytags.yaml", "easytags.yml"}
+
+// TagConfig describes how a single struct tag should be generated when
+// driven by a config file. Case and Template are mutually exclusive:
+// when Template is set it takes precedence and Case is ignored.
+type TagConfig struct {
+	Case     string `toml:"case" yaml:"case"`
+	Template string `toml:"template" yaml:"template"`
+	Default  string `toml:"default" yaml:"default"`
+}
+
+// FieldRule restricts which fields a config or Options applies to.
+type FieldRule struct {
+	Include string `toml:"include" yaml:"include"`
+	Exclude string `toml:"exclude" yaml:"exclude"`
+}
+
+// Config is the on-disk representation of an easytags.toml/easytags.yaml
+// file: the set of tags to generate, keyed by tag name, plus global
+// field selection rules.
+type Config struct {
+	Omitempty bool                 `toml:"omitempty" yaml:"omitempty"`
+	Tags      map[string]TagConfig `toml:"tags" yaml:"tags"`
+	Fields    FieldRule            `toml:"fields" yaml:"fields"`
+}
+
+// FindConfig looks for a config file alongside dir, trying configNames in
+// order. It returns "" if none exist.
+func FindConfig(dir string) string {
+	for _, name := range configNames {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// LoadConfig reads and parses the config file at path. The format is
+// chosen from the file extension (.toml, .yaml, .yml).
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("easytags: reading config %s: %w", path, err)
+	}
+
+	cfg := &Config{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		if err := toml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("easytags: parsing toml config %s: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("easytags: parsing yaml config %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("easytags: unsupported config extension %q", ext)
+	}
+	return cfg, nil
+}
+
+// TagOpts converts the config into the []*TagOpt shape that Options
+// consumes, so a config file can fully replace the positional
+// tag:case arguments. Tags are emitted in sorted key order, since
+// c.Tags is a map and ranging over it directly would make the tool
+// non-deterministic: the same config would add tags to a field in a
+// different order on every run.
+func (c *Config) TagOpts() []*TagOpt {
+	names := make([]string, 0, len(c.Tags))
+	for name := range c.Tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tagOpts := make([]*TagOpt, 0, len(names))
+	for _, name := range names {
+		tc := c.Tags[name]
+		tagOpts = append(tagOpts, &TagOpt{
+			Tag:      name,
+			Case:     tc.Case,
+			Template: tc.Template,
+			Default:  tc.Default,
+		})
+	}
+	return tagOpts
+}
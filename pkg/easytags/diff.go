@@ -0,0 +1,112 @@
+package easytags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diff rewrites filename's struct tags per opts without touching disk,
+// returning a line-by-line diff against the file's current contents. changed
+// is false (and diff empty) when the rewrite wouldn't alter the file —
+// the case --check/--dry-run callers use to decide their exit code.
+func Diff(filename string, opts Options) (diff string, changed bool, err error) {
+	before, err := readFile(filename)
+	if err != nil {
+		return "", false, err
+	}
+
+	after, err := rewriteSource(filename, before, opts)
+	if err != nil {
+		return "", false, fmt.Errorf("easytags: %s: %w", filename, err)
+	}
+
+	if string(before) == string(after) {
+		return "", false, nil
+	}
+
+	return unifiedDiff(filename, string(before), string(after)), true, nil
+}
+
+// unifiedDiff renders a diff between before and after, computed from a
+// classic line-based LCS. After "--- a/" and "+++ b/" headers it lists
+// every line of the file, prefixed "  " (unchanged), "- " or "+ "; it
+// writes no @@ hunk headers and does not trim unchanged lines. It's aimed
+// at the single struct-tag edits this tool makes, not large-scale refactors.
+func unifiedDiff(filename, before, after string) string {
+	a := strings.Split(before, "\n")
+	b := strings.Split(after, "\n")
+	ops := diffLines(a, b)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- a/%s\n", filename)
+	fmt.Fprintf(&buf, "+++ b/%s\n", filename)
+	for _, op := range ops {
+		switch op.kind {
+		case opEqual:
+			fmt.Fprintf(&buf, "  %s\n", op.line)
+		case opDelete:
+			fmt.Fprintf(&buf, "- %s\n", op.line)
+		case opInsert:
+			fmt.Fprintf(&buf, "+ %s\n", op.line)
+		}
+	}
+	return buf.String()
+}
+
+type diffOpKind int
+
+const (
+	opEqual diffOpKind = iota
+	opDelete
+	opInsert
+)
+
+type diffOp struct {
+	kind diffOpKind
+	line string
+}
+
+// diffLines computes a line-level edit script from a to b using a
+// straightforward longest-common-subsequence table.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{opDelete, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{opInsert, b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,423 @@
+// Package easytags generates and rewrites Go struct tags. It is the
+// library underneath the easytags CLI and the easytags-lsp language
+// server: both drive the same Rewrite/RewriteStruct/RewriteFile API so
+// editor integrations don't need to shell out and overwrite files.
+package easytags
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	structtag "github.com/win5do/easytags/pkg/tags"
+)
+
+// TagOpt describes one tag to generate. Template, when set (via a config
+// file), overrides Case and is rendered as the tag's value.
+type TagOpt struct {
+	Tag      string
+	Case     string
+	Template string
+	Default  string
+}
+
+// OptionMutation describes an AddOptions/RemoveOptions request for a
+// single existing tag, e.g. "json=omitempty,string".
+type OptionMutation struct {
+	Tag     string
+	Options []string
+}
+
+// Options bundles everything a single rewrite needs: which tags to
+// generate, which existing tags to mutate in place, and which fields to
+// touch at all.
+type Options struct {
+	Tags           []*TagOpt
+	Remove         bool
+	Omitempty      bool
+	FieldRule      *FieldRule
+	SkipUnexported bool
+	AddOptions     []*OptionMutation
+	RemoveOptions  []*OptionMutation
+	ClearOptions   []string
+}
+
+// ParseOptionMutations turns "tag=opt1,opt2" flag values into
+// OptionMutations. A value with no "=opts" part yields an OptionMutation
+// with no Options.
+func ParseOptionMutations(values []string) ([]*OptionMutation, error) {
+	var mutations []*OptionMutation
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		m := &OptionMutation{Tag: strings.TrimSpace(parts[0])}
+		if m.Tag == "" {
+			return nil, fmt.Errorf("easytags: invalid option mutation %q", v)
+		}
+		if len(parts) == 2 && parts[1] != "" {
+			m.Options = strings.Split(parts[1], ",")
+		}
+		mutations = append(mutations, m)
+	}
+	return mutations, nil
+}
+
+// Rewrite parses src as a Go source file and rewrites the struct tags of
+// every struct type in it, returning the result. Unlike an approach built
+// on format.Node, Rewrite only replaces the byte ranges of the field.Tag
+// literals it actually changes, so everything else in src — blank lines,
+// comment placement, //go:build spacing — passes through untouched.
+func Rewrite(src []byte, opts Options) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("easytags: parsing source: %w", err)
+	}
+
+	edits, err := collectEdits(fset, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	return applyEdits(src, edits), nil
+}
+
+// RewriteFile reads filename, rewrites its struct tags according to
+// opts, and writes the result back atomically (via a temp file and
+// rename), so a failed or interrupted write can never leave filename
+// truncated or half-written. If filename fails to parse, RewriteFile
+// returns that error and leaves the file untouched.
+func RewriteFile(filename string, opts Options) error {
+	src, err := readFile(filename)
+	if err != nil {
+		return err
+	}
+
+	out, err := rewriteSource(filename, src, opts)
+	if err != nil {
+		return fmt.Errorf("easytags: %s: %w", filename, err)
+	}
+
+	return writeFileAtomic(filename, out)
+}
+
+// rewriteSource is the common path RewriteFile and Diff share: it rewrites
+// src with Rewrite and, if that fails to parse, retries via
+// rewriteWithPackages, which understands build tags and cgo.
+func rewriteSource(filename string, src []byte, opts Options) ([]byte, error) {
+	out, err := Rewrite(src, opts)
+	if err == nil {
+		return out, nil
+	}
+	if out, perr := rewriteWithPackages(filename, src, opts); perr == nil {
+		return out, nil
+	}
+	return nil, err
+}
+
+// tagEdit replaces src[start:end] with text. end == start for an edit that
+// inserts a tag onto a field that previously had none.
+type tagEdit struct {
+	start, end int
+	text       string
+}
+
+// collectEdits walks every struct type in f and collects the tag edits
+// opts calls for.
+func collectEdits(fset *token.FileSet, f *ast.File, opts Options) ([]tagEdit, error) {
+	var edits []tagEdit
+	var walkErr error
+	ast.Inspect(f, func(n ast.Node) bool {
+		if walkErr != nil {
+			return false
+		}
+		if t, ok := n.(*ast.StructType); ok {
+			e, err := collectStructEdits(fset, t, opts)
+			if err != nil {
+				walkErr = err
+				return false
+			}
+			edits = append(edits, e...)
+			return false
+		}
+		return true
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return edits, nil
+}
+
+// collectStructEdits mirrors RewriteStruct's field selection and tag
+// computation, but instead of mutating the AST it records the byte range
+// each field's tag literal occupies in the original source (or, for a
+// field with no tag yet, the insertion point right after its type).
+func collectStructEdits(fset *token.FileSet, x *ast.StructType, opts Options) ([]tagEdit, error) {
+	var edits []tagEdit
+	for _, field := range x.Fields.List {
+		if len(field.Names) == 0 {
+			continue
+		}
+		if opts.SkipUnexported && !unicode.IsUpper(rune(field.Names[0].String()[0])) {
+			continue
+		}
+		if !fieldAllowed(opts.FieldRule, field.Names[0].String()) {
+			continue
+		}
+
+		hadTag := field.Tag != nil
+		var oldValue string
+		if hadTag && !opts.Remove {
+			oldValue = field.Tag.Value
+		}
+
+		newValue, err := rewriteTagValue(field.Names[0].String(), oldValue, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case newValue == "" && !hadTag:
+			// Nothing to add and nothing to remove.
+			continue
+		case newValue == "":
+			// The field had a tag and ends up with none: delete the
+			// whole " `...`" span, including the separating space, so
+			// the field goes tag-less instead of keeping a stray empty tag.
+			edits = append(edits, tagEdit{
+				start: fset.Position(field.Type.End()).Offset,
+				end:   fset.Position(field.Tag.End()).Offset,
+				text:  "",
+			})
+		case hadTag:
+			if !opts.Remove && newValue == field.Tag.Value {
+				continue
+			}
+			edits = append(edits, tagEdit{
+				start: fset.Position(field.Tag.Pos()).Offset,
+				end:   fset.Position(field.Tag.End()).Offset,
+				text:  newValue,
+			})
+		default:
+			at := fset.Position(field.Type.End()).Offset
+			edits = append(edits, tagEdit{start: at, end: at, text: " " + newValue})
+		}
+	}
+	return edits, nil
+}
+
+// applyEdits splices edits into src, replacing each edit's byte range with
+// its text. Edits need not arrive in source order.
+func applyEdits(src []byte, edits []tagEdit) []byte {
+	if len(edits) == 0 {
+		return src
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var buf bytes.Buffer
+	pos := 0
+	for _, e := range edits {
+		buf.Write(src[pos:e.start])
+		buf.WriteString(e.text)
+		pos = e.end
+	}
+	buf.Write(src[pos:])
+	return buf.Bytes()
+}
+
+// readFile reads filename, wrapping any error with its path.
+func readFile(filename string) ([]byte, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("easytags: reading %s: %w", filename, err)
+	}
+	return src, nil
+}
+
+// writeFileAtomic writes data to filename by writing a temp file in the
+// same directory and renaming it over filename, preserving filename's
+// existing permissions. This avoids ever leaving filename truncated if
+// the write is interrupted partway through.
+func writeFileAtomic(filename string, data []byte) (err error) {
+	mode := os.FileMode(0o644)
+	if info, statErr := os.Stat(filename); statErr == nil {
+		mode = info.Mode()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("easytags: creating temp file for %s: %w", filename, err)
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		if err != nil {
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("easytags: writing %s: %w", filename, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("easytags: writing %s: %w", filename, err)
+	}
+	if err = os.Chmod(tmpName, mode); err != nil {
+		return fmt.Errorf("easytags: writing %s: %w", filename, err)
+	}
+	if err = os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("easytags: writing %s: %w", filename, err)
+	}
+	return nil
+}
+
+// RewriteStruct rewrites the tags of every field in x according to opts.
+// A field ends up tag-less, rather than carrying a stray empty tag
+// literal, when it had no tag and opts.Tags adds none, or when
+// opts.Remove empties out a tag it did have. It reports an error (and
+// leaves x unmodified from that field on) if opts.Tags names a case
+// that isn't in caseRegistry.
+func RewriteStruct(x *ast.StructType, opts Options) error {
+	for _, field := range x.Fields.List {
+		if len(field.Names) == 0 {
+			continue
+		}
+		if opts.SkipUnexported && !unicode.IsUpper(rune(field.Names[0].String()[0])) {
+			continue
+		}
+		if !fieldAllowed(opts.FieldRule, field.Names[0].String()) {
+			continue
+		}
+
+		hadTag := field.Tag != nil
+		var oldValue string
+		if hadTag && !opts.Remove {
+			oldValue = field.Tag.Value
+		}
+
+		newValue, err := rewriteTagValue(field.Names[0].String(), oldValue, opts)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case newValue == "":
+			field.Tag = nil
+		case !hadTag:
+			field.Tag = &ast.BasicLit{ValuePos: field.Type.Pos() + 1, Kind: token.STRING, Value: newValue}
+		default:
+			field.Tag.Value = newValue
+		}
+	}
+	return nil
+}
+
+// rewriteTagValue is a thin driver over pkg/tags: it parses oldValue (a
+// field's existing tag literal, or "" if it has none), applies any
+// AddOptions/RemoveOptions/ClearOptions mutations to tags that already
+// exist, fills in any tag requested by opts.Tags that isn't already
+// present, and re-emits the result as a new tag literal. It never
+// regenerates the name of a tag that already exists. It returns an
+// empty string, not an empty tag literal, when no tags remain, so the
+// caller can leave the field tag-less.
+func rewriteTagValue(fieldName, oldValue string, opts Options) (string, error) {
+	parsed, err := structtag.Parse(strings.Trim(oldValue, "`"))
+	if err != nil {
+		parsed, _ = structtag.Parse("")
+	}
+
+	for _, m := range opts.AddOptions {
+		_ = parsed.AddOption(m.Tag, m.Options...)
+	}
+	for _, m := range opts.RemoveOptions {
+		_ = parsed.RemoveOption(m.Tag, m.Options...)
+	}
+	for _, key := range opts.ClearOptions {
+		_ = parsed.ClearOptions(key)
+	}
+
+	for _, opt := range opts.Tags {
+		if _, err := parsed.Get(opt.Tag); err == nil {
+			continue
+		}
+
+		var value string
+		if opt.Template != "" {
+			rendered, err := renderTagTemplate(opt, fieldName)
+			if err != nil {
+				continue
+			}
+			value = rendered
+		} else {
+			fn, ok := caseRegistry[opt.Case]
+			if !ok {
+				return "", fmt.Errorf("easytags: unknown case %q for tag %q", opt.Case, opt.Tag)
+			}
+			name := fn(fieldName)
+			if opts.Omitempty {
+				value = name + ",omitempty"
+			} else {
+				value = name
+			}
+		}
+
+		parts := strings.Split(value, ",")
+		newTag := &structtag.Tag{Key: opt.Tag, Name: parts[0], Options: parts[1:]}
+		_ = parsed.Set(newTag)
+	}
+
+	if body := parsed.String(); body != "" {
+		return "`" + body + "`", nil
+	}
+	return "", nil
+}
+
+// renderTagTemplate executes tag.Template as a text/template, making the
+// field name and the tag's configured default value available, alongside
+// the case transforms so a template can do e.g. {{.snake}}.
+func renderTagTemplate(tag *TagOpt, fieldName string) (string, error) {
+	tmpl, err := template.New(tag.Tag).Parse(tag.Template)
+	if err != nil {
+		return "", fmt.Errorf("easytags: parsing template for tag %q: %w", tag.Tag, err)
+	}
+
+	data := map[string]string{
+		"FieldName":    fieldName,
+		"DefaultValue": tag.Default,
+		"snake":        ToSnake(fieldName),
+		"camel":        ToCamel(fieldName),
+		"pascal":       ToPascal(fieldName),
+		"kebab":        ToKebab(fieldName),
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("easytags: rendering template for tag %q: %w", tag.Tag, err)
+	}
+	return buf.String(), nil
+}
+
+// fieldAllowed reports whether fieldName should be processed under rule.
+// An empty rule allows everything; Exclude is checked before Include.
+func fieldAllowed(rule *FieldRule, fieldName string) bool {
+	if rule == nil {
+		return true
+	}
+	if rule.Exclude != "" {
+		if matched, _ := regexp.MatchString(rule.Exclude, fieldName); matched {
+			return false
+		}
+	}
+	if rule.Include != "" {
+		matched, _ := regexp.MatchString(rule.Include, fieldName)
+		return matched
+	}
+	return true
+}
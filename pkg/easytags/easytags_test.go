@@ -0,0 +1,175 @@
+package easytags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteAddsTags(t *testing.T) {
+	src := `package p
+
+type User struct {
+	ID   string
+	Name string
+}
+`
+	opts := Options{SkipUnexported: true, Tags: []*TagOpt{{Tag: "json", Case: CaseSnake}}}
+	out, err := Rewrite([]byte(src), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ID   string `json:\"id\"`"
+	if !strings.Contains(string(out), want) {
+		t.Errorf("Rewrite output = %s, want it to contain %q", out, want)
+	}
+}
+
+func TestRewritePreservesUnrelatedFormatting(t *testing.T) {
+	src := `package p
+
+// Comment kept as-is.
+
+type User struct {
+
+	ID string
+}
+`
+	opts := Options{SkipUnexported: true, Tags: []*TagOpt{{Tag: "json", Case: CaseSnake}}}
+	out, err := Rewrite([]byte(src), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "// Comment kept as-is.") {
+		t.Errorf("Rewrite output dropped the comment: %s", out)
+	}
+	if !strings.Contains(string(out), "type User struct {\n\n\tID string") {
+		t.Errorf("Rewrite output collapsed the blank line inside the struct: %s", out)
+	}
+}
+
+func TestRewriteRemove(t *testing.T) {
+	src := `package p
+
+type User struct {
+	ID    string
+	Other int ` + "`json:\"x\" xml:\"y\"`" + `
+}
+`
+	opts := Options{SkipUnexported: true, Remove: true}
+	out, err := Rewrite([]byte(src), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	if strings.Contains(got, "`") {
+		t.Errorf("Rewrite with Remove left a tag literal behind: %s", got)
+	}
+	if !strings.Contains(got, "ID    string") {
+		t.Errorf("Rewrite with Remove touched a field that had no tag: %s", got)
+	}
+	if !strings.Contains(got, "Other int\n") {
+		t.Errorf("Rewrite with Remove didn't strip the tag cleanly: %s", got)
+	}
+}
+
+func TestRewriteOptionMutations(t *testing.T) {
+	src := `package p
+
+type User struct {
+	Name string ` + "`json:\"name,omitempty\"`" + `
+	Age  int    ` + "`json:\"age,omitempty\"`" + `
+}
+`
+	opts := Options{
+		SkipUnexported: true,
+		AddOptions:     []*OptionMutation{{Tag: "json", Options: []string{"string"}}},
+		RemoveOptions:  []*OptionMutation{{Tag: "json", Options: []string{"omitempty"}}},
+	}
+	out, err := Rewrite([]byte(src), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	if !strings.Contains(got, `json:"name,string"`) {
+		t.Errorf("Rewrite output = %s, want json:\"name,string\"", got)
+	}
+	if !strings.Contains(got, `json:"age,string"`) {
+		t.Errorf("Rewrite output = %s, want json:\"age,string\"", got)
+	}
+}
+
+func TestRewriteClearOptions(t *testing.T) {
+	src := `package p
+
+type User struct {
+	Name string ` + "`json:\"name,omitempty,string\"`" + `
+}
+`
+	opts := Options{SkipUnexported: true, ClearOptions: []string{"json"}}
+	out, err := Rewrite([]byte(src), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := `json:"name"`, string(out); !strings.Contains(got, want) {
+		t.Errorf("Rewrite output = %s, want it to contain %q", got, want)
+	}
+}
+
+func TestRewriteTemplate(t *testing.T) {
+	src := `package p
+
+type User struct {
+	ID string
+}
+`
+	opts := Options{
+		SkipUnexported: true,
+		Tags: []*TagOpt{{
+			Tag:      "gorm",
+			Template: `column:{{.snake}};default:{{.DefaultValue}}`,
+			Default:  "0",
+		}},
+	}
+	out, err := Rewrite([]byte(src), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `gorm:"column:id;default:0"`
+	if !strings.Contains(string(out), want) {
+		t.Errorf("Rewrite output = %s, want it to contain %q", out, want)
+	}
+}
+
+func TestRewriteUnknownCase(t *testing.T) {
+	src := `package p
+
+type User struct {
+	ID string
+}
+`
+	opts := Options{SkipUnexported: true, Tags: []*TagOpt{{Tag: "json", Case: "not-a-case"}}}
+	if _, err := Rewrite([]byte(src), opts); err == nil {
+		t.Error("Rewrite with an unknown case = nil error, want error")
+	}
+}
+
+func TestRewriteIsIdempotent(t *testing.T) {
+	src := `package p
+
+type User struct {
+	ID string
+}
+`
+	opts := Options{SkipUnexported: true, Tags: []*TagOpt{{Tag: "json", Case: CaseSnake}}}
+	first, err := Rewrite([]byte(src), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Rewrite(first, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("Rewrite isn't idempotent: first=%s second=%s", first, second)
+	}
+}
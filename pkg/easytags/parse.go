@@ -0,0 +1,52 @@
+package easytags
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// rewriteWithPackages is the fallback parse path for files go/parser's bare
+// ParseFile can't make sense of on its own: ones gated by a //go:build
+// constraint for another GOOS/GOARCH, or ones using cgo's "import C"
+// preprocessing. packages.Load drives the same loading the go command
+// itself uses, so it resolves build constraints and expands cgo before we
+// ever see the AST.
+func rewriteWithPackages(filename string, src []byte, opts Options) ([]byte, error) {
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedSyntax | packages.NeedFiles,
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, "file="+filename)
+	if err != nil {
+		return nil, fmt.Errorf("easytags: loading %s: %w", filename, err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("easytags: %s: no package found", filename)
+	}
+
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, fmt.Errorf("easytags: %s: %v", filename, pkg.Errors[0])
+	}
+
+	var target *ast.File
+	for i, goFile := range pkg.GoFiles {
+		if goFile == filename {
+			target = pkg.Syntax[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("easytags: %s: not found in its own package", filename)
+	}
+
+	edits, err := collectEdits(fset, target, opts)
+	if err != nil {
+		return nil, err
+	}
+	return applyEdits(src, edits), nil
+}
@@ -0,0 +1,212 @@
+// Package tags implements a small, order-preserving struct tag parser and
+// writer, modeled on github.com/fatih/structtag. It replaces ad-hoc regex
+// mutation of `field.Tag.Value` with a proper AST for the tag string, so
+// callers can safely inspect, add, or remove individual tags and options
+// without corrupting neighbouring tags or duplicating options on re-run.
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrTagNotExist is returned by Get when the requested key has no tag.
+var ErrTagNotExist = errors.New("tags: tag does not exist")
+
+// Tag represents a single key:"name,option,option" entry.
+type Tag struct {
+	// Key is the tag key, e.g. "json" in `json:"name,omitempty"`.
+	Key string
+	// Name is the tag value's leading, unnamed field, e.g. "name".
+	Name string
+	// Options are the remaining comma-separated parts of the value.
+	Options []string
+}
+
+// HasOption reports whether opt is present in the tag's options.
+func (t *Tag) HasOption(opt string) bool {
+	for _, o := range t.Options {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// String reassembles the tag's value (without the surrounding quotes),
+// e.g. "name,omitempty".
+func (t *Tag) String() string {
+	if t == nil {
+		return ""
+	}
+	var buf strings.Builder
+	buf.WriteString(t.Name)
+	for _, o := range t.Options {
+		buf.WriteByte(',')
+		buf.WriteString(o)
+	}
+	return buf.String()
+}
+
+// Tags is an ordered collection of Tag entries parsed from a struct tag.
+type Tags struct {
+	tags []*Tag
+}
+
+// Parse parses a raw struct tag (without backticks) into an ordered list
+// of Tag entries, e.g. `json:"name,omitempty" gorm:"column:x"`.
+func Parse(tag string) (*Tags, error) {
+	var parsed []*Tag
+
+	for tag != "" {
+		// skip leading space, mirroring reflect.StructTag.Lookup
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// scan to colon-quote, which marks the end of the key
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, fmt.Errorf("tags: invalid tag syntax near %q", tag)
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// scan the quoted value, honouring escaped quotes
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, fmt.Errorf("tags: unterminated quoted value for key %q", key)
+		}
+		quoted := tag[:i+1]
+		tag = tag[i+1:]
+
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("tags: invalid quoted value for key %q: %w", key, err)
+		}
+
+		parts := strings.Split(value, ",")
+		parsed = append(parsed, &Tag{Key: key, Name: parts[0], Options: parts[1:]})
+	}
+
+	return &Tags{tags: parsed}, nil
+}
+
+// Tags returns the parsed entries in their original order.
+func (t *Tags) Tags() []*Tag {
+	return t.tags
+}
+
+// Get returns the tag for key, or ErrTagNotExist if it isn't present.
+func (t *Tags) Get(key string) (*Tag, error) {
+	for _, tg := range t.tags {
+		if tg.Key == key {
+			return tg, nil
+		}
+	}
+	return nil, ErrTagNotExist
+}
+
+// Set replaces the tag with the same key, or appends it if none exists.
+func (t *Tags) Set(tag *Tag) error {
+	if tag == nil {
+		return errors.New("tags: tag must not be nil")
+	}
+	for i, tg := range t.tags {
+		if tg.Key == tag.Key {
+			t.tags[i] = tag
+			return nil
+		}
+	}
+	t.tags = append(t.tags, tag)
+	return nil
+}
+
+// Delete removes the tags matching any of keys, if present.
+func (t *Tags) Delete(keys ...string) {
+	remove := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		remove[k] = struct{}{}
+	}
+
+	kept := t.tags[:0]
+	for _, tg := range t.tags {
+		if _, ok := remove[tg.Key]; ok {
+			continue
+		}
+		kept = append(kept, tg)
+	}
+	t.tags = kept
+}
+
+// AddOption appends options to the tag at key, skipping any it already has.
+func (t *Tags) AddOption(key string, options ...string) error {
+	tg, err := t.Get(key)
+	if err != nil {
+		return err
+	}
+	for _, opt := range options {
+		if !tg.HasOption(opt) {
+			tg.Options = append(tg.Options, opt)
+		}
+	}
+	return nil
+}
+
+// RemoveOption strips options from the tag at key, leaving its name intact.
+func (t *Tags) RemoveOption(key string, options ...string) error {
+	tg, err := t.Get(key)
+	if err != nil {
+		return err
+	}
+	remove := make(map[string]struct{}, len(options))
+	for _, opt := range options {
+		remove[opt] = struct{}{}
+	}
+
+	kept := tg.Options[:0]
+	for _, opt := range tg.Options {
+		if _, ok := remove[opt]; ok {
+			continue
+		}
+		kept = append(kept, opt)
+	}
+	tg.Options = kept
+	return nil
+}
+
+// ClearOptions drops all options from the tag at key, keeping its name.
+func (t *Tags) ClearOptions(key string) error {
+	tg, err := t.Get(key)
+	if err != nil {
+		return err
+	}
+	tg.Options = nil
+	return nil
+}
+
+// String reassembles the full struct tag body (without backticks), e.g.
+// `json:"name,omitempty" gorm:"column:x"`.
+func (t *Tags) String() string {
+	parts := make([]string, 0, len(t.tags))
+	for _, tg := range t.tags {
+		parts = append(parts, fmt.Sprintf("%s:%q", tg.Key, tg.String()))
+	}
+	return strings.Join(parts, " ")
+}
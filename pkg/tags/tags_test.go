@@ -0,0 +1,154 @@
+package tags
+
+import "testing"
+
+func TestParseAndString(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"simple", `json:"name"`, `json:"name"`},
+		{"options", `json:"name,omitempty"`, `json:"name,omitempty"`},
+		{"multiple tags preserve order", `json:"name" xml:"name"`, `json:"name" xml:"name"`},
+		{"escaped quote in value", `json:"na\"me"`, `json:"na\"me"`},
+		{"colon inside value", `gorm:"column:x"`, `gorm:"column:x"`},
+		{"empty", ``, ``},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			parsed, err := Parse(c.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", c.in, err)
+			}
+			if got := parsed.String(); got != c.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	cases := []string{
+		`json`,
+		`json:name`,
+		`json:"name`,
+	}
+	for _, in := range cases {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = nil error, want error", in)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	parsed, err := Parse(`json:"name" gorm:"column:x"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tg, err := parsed.Get("json")
+	if err != nil {
+		t.Fatalf("Get(json) returned error: %v", err)
+	}
+	if tg.Name != "name" {
+		t.Errorf("Get(json).Name = %q, want %q", tg.Name, "name")
+	}
+
+	if _, err := parsed.Get("bson"); err != ErrTagNotExist {
+		t.Errorf("Get(bson) error = %v, want ErrTagNotExist", err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	parsed, err := Parse(`json:"name"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Set on an existing key replaces it in place, preserving order.
+	if err := parsed.Set(&Tag{Key: "json", Name: "renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.Set(&Tag{Key: "xml", Name: "name"}); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := `json:"renamed" xml:"name"`, parsed.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	parsed, err := Parse(`json:"name" gorm:"column:x" xml:"name"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed.Delete("gorm")
+	if want, got := `json:"name" xml:"name"`, parsed.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestAddOptionDoesNotDuplicateOnRerun(t *testing.T) {
+	parsed, err := Parse(`json:"name,omitempty"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-running AddOption with an option the tag already has (the
+	// regex-based mutator's bug: re-running easytags -o would append a
+	// second ",omitempty") must be a no-op.
+	if err := parsed.AddOption("json", "omitempty"); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := `json:"name,omitempty"`, parsed.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if err := parsed.AddOption("json", "string"); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := `json:"name,omitempty,string"`, parsed.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if err := parsed.AddOption("bson", "omitempty"); err != ErrTagNotExist {
+		t.Errorf("AddOption on missing key error = %v, want ErrTagNotExist", err)
+	}
+}
+
+func TestRemoveOption(t *testing.T) {
+	parsed, err := Parse(`json:"name,omitempty,string"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.RemoveOption("json", "omitempty"); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := `json:"name,string"`, parsed.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestClearOptions(t *testing.T) {
+	parsed, err := Parse(`json:"name,omitempty,string"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.ClearOptions("json"); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := `json:"name"`, parsed.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestHasOption(t *testing.T) {
+	tg := &Tag{Name: "name", Options: []string{"omitempty"}}
+	if !tg.HasOption("omitempty") {
+		t.Error("HasOption(omitempty) = false, want true")
+	}
+	if tg.HasOption("string") {
+		t.Error("HasOption(string) = true, want false")
+	}
+}